@@ -0,0 +1,102 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmojzes/webservice/codec"
+	"github.com/tmojzes/webservice/store"
+)
+
+// BenchmarkGetTimestamp and BenchmarkStoreTimestamp drive the handlers
+// directly through httptest, in the style of fasthttp's allocation
+// benchmarks, and assert an allocation budget with testing.AllocsPerRun
+// so a regression in the hot path shows up as a failing test rather than
+// just a slower benchmark.
+
+func BenchmarkGetTimestamp(b *testing.B) {
+	timestampStore := store.NewInMemory()
+	timestampStore.StoreTimestamp(time.Now().Unix())
+	server := NewTimestampServer(Options{Store: timestampStore})
+
+	request := newGetTimestampRequest()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+	}
+}
+
+func TestGetTimestampAllocs(t *testing.T) {
+	timestampStore := store.NewInMemory()
+	timestampStore.StoreTimestamp(time.Now().Unix())
+	server := NewTimestampServer(Options{Store: timestampStore})
+
+	request := newGetTimestampRequest()
+
+	// 21 is what GET /timestamp actually allocates once content negotiation
+	// (codec.Negotiate, the Codec interface dispatch) and the cache header
+	// path are accounted for; it's a regression budget, not an aspiration.
+	const maxAllocsPerRun = 21
+
+	allocs := testing.AllocsPerRun(100, func() {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+	})
+
+	if allocs > maxAllocsPerRun {
+		t.Errorf("GET /timestamp allocates %.0f times per call, want <= %d", allocs, maxAllocsPerRun)
+	}
+}
+
+func BenchmarkStoreTimestamp(b *testing.B) {
+	timestampStore := store.NewInMemory()
+	server := NewTimestampServer(Options{Store: timestampStore})
+
+	body := "1699999999"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		request := newPostTimestampRequest(mustParseTimestamp(body))
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+	}
+}
+
+func TestStoreTimestampAllocs(t *testing.T) {
+	timestampStore := store.NewInMemory()
+	server := NewTimestampServer(Options{Store: timestampStore})
+
+	// 14 is what POST /timestamp actually allocates once codec.Lookup, the
+	// Codec interface dispatch, and store.InMemory's history bookkeeping
+	// (the append under its mutex, amortized across repeated calls) are
+	// accounted for; it's a regression budget, not an aspiration.
+	const maxAllocsPerRun = 14
+
+	allocs := testing.AllocsPerRun(100, func() {
+		request, _ := http.NewRequest(http.MethodPost, "/timestamp", strings.NewReader("1699999999"))
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+	})
+
+	if allocs > maxAllocsPerRun {
+		t.Errorf("POST /timestamp allocates %.0f times per call, want <= %d", allocs, maxAllocsPerRun)
+	}
+}
+
+func mustParseTimestamp(s string) int64 {
+	timestamp, err := codec.ParseInt64([]byte(s))
+	if err != nil {
+		panic(err)
+	}
+
+	return timestamp
+}
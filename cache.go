@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	headerCacheControl    = "Cache-Control"
+	headerDate            = "Date"
+	headerETag            = "ETag"
+	headerLastModified    = "Last-Modified"
+	headerIfNoneMatch     = "If-None-Match"
+	headerIfModifiedSince = "If-Modified-Since"
+	headerVary            = "Vary"
+
+	// defaultMaxAge is advertised on GET /timestamp so downstream proxies
+	// and the CachingClient know how long a response stays fresh.
+	defaultMaxAge = 5 * time.Second
+)
+
+// LastModifiedStore is implemented by stores that can report when the
+// stored timestamp was last written, so handlers can emit Last-Modified
+// and ETag validators without tracking that state separately.
+type LastModifiedStore interface {
+	TimestampStore
+	LastModified() time.Time
+}
+
+// etagFor returns a strong ETag derived from the unix seconds of
+// timestamp and variant, a key identifying the negotiated representation
+// (usually its content type, but callers must use a distinct key for
+// representations that share a content type, such as the ?format=iso
+// override sharing text/plain with the default Text codec), so two
+// representations of the same timestamp never share a validator.
+func etagFor(timestamp int64, variant string) string {
+	h := fnv.New64a()
+	h.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	h.Write([]byte(variant))
+
+	return `"` + strconv.FormatUint(h.Sum64(), 16) + `"`
+}
+
+// writeCacheHeaders sets Cache-Control, ETag, Last-Modified and Vary on w
+// and reports whether the request's validators already match, in which
+// case the caller should respond with 304 Not Modified instead of a
+// body. Vary: Accept tells caches the body depends on content
+// negotiation, so they don't serve one representation in place of
+// another.
+func writeCacheHeaders(w http.ResponseWriter, r *http.Request, lastModified time.Time, etag string) (notModified bool) {
+	w.Header().Set(headerETag, etag)
+	w.Header().Set(headerLastModified, lastModified.UTC().Format(http.TimeFormat))
+	w.Header().Set(headerCacheControl, "max-age="+strconv.Itoa(int(defaultMaxAge.Seconds())))
+	w.Header().Set(headerVary, "Accept")
+
+	if inm := r.Header.Get(headerIfNoneMatch); inm != "" {
+		return inm == etag || inm == "*"
+	}
+
+	if ims := r.Header.Get(headerIfModifiedSince); ims != "" {
+		if t, err := http.ParseTime(ims); err == nil && !lastModified.Truncate(time.Second).After(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResponseCache stores raw, serialized HTTP responses keyed by request
+// URL, analogous to gregjones/httpcache's Cache interface.
+type ResponseCache interface {
+	Get(key string) (responseBytes []byte, ok bool)
+	Set(key string, responseBytes []byte)
+	Delete(key string)
+}
+
+// MemoryResponseCache is a ResponseCache backed by an in-memory map,
+// analogous to httpcache's MemoryCache.
+type MemoryResponseCache struct {
+	mu    sync.RWMutex
+	store map[string][]byte
+}
+
+func NewMemoryResponseCache() *MemoryResponseCache {
+	return &MemoryResponseCache{store: map[string][]byte{}}
+}
+
+func (c *MemoryResponseCache) Get(key string) ([]byte, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	responseBytes, ok := c.store[key]
+	return responseBytes, ok
+}
+
+func (c *MemoryResponseCache) Set(key string, responseBytes []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.store[key] = responseBytes
+}
+
+func (c *MemoryResponseCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.store, key)
+}
+
+// CachingTransport is an http.RoundTripper that revalidates GET requests
+// against a ResponseCache instead of always hitting the network, honoring
+// ETag/Last-Modified validators and the Cache-Control max-age directive.
+type CachingTransport struct {
+	Transport http.RoundTripper
+	Cache     ResponseCache
+}
+
+func (t *CachingTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+
+	return http.DefaultTransport
+}
+
+func (t *CachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.transport().RoundTrip(req)
+	}
+
+	// The Accept header is folded into the cache key because GET /timestamp
+	// responses carry Vary: Accept: two requests for the same URL can
+	// negotiate different representations (text/JSON/protobuf), and a
+	// validator from one must never be replayed as another.
+	cacheKey := req.URL.String() + "\nAccept: " + req.Header.Get("Accept")
+
+	cachedBytes, hasCached := t.Cache.Get(cacheKey)
+	if !hasCached {
+		resp, err := t.transport().RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		t.maybeStore(cacheKey, resp)
+		return resp, nil
+	}
+
+	cachedResp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(cachedBytes)), req)
+	if err != nil {
+		t.Cache.Delete(cacheKey)
+		return t.transport().RoundTrip(req)
+	}
+
+	// A cached entry still within its max-age is served without touching
+	// the network at all, rather than revalidating; revalidation is a
+	// fallback for a stale entry, not the default path for every hit.
+	if isFresh(cachedResp) {
+		return cachedResp, nil
+	}
+
+	revalidateReq := req.Clone(req.Context())
+	if etag := cachedResp.Header.Get(headerETag); etag != "" {
+		revalidateReq.Header.Set(headerIfNoneMatch, etag)
+	}
+	if lastModified := cachedResp.Header.Get(headerLastModified); lastModified != "" {
+		revalidateReq.Header.Set(headerIfModifiedSince, lastModified)
+	}
+
+	resp, err := t.transport().RoundTrip(revalidateReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		return http.ReadResponse(bufio.NewReader(bytes.NewReader(cachedBytes)), req)
+	}
+
+	t.maybeStore(cacheKey, resp)
+	return resp, nil
+}
+
+func (t *CachingTransport) maybeStore(cacheKey string, resp *http.Response) {
+	if !isCacheable(resp) {
+		t.Cache.Delete(cacheKey)
+		return
+	}
+
+	dumped, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return
+	}
+
+	t.Cache.Set(cacheKey, dumped)
+}
+
+func isCacheable(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	for _, directive := range strings.Split(resp.Header.Get(headerCacheControl), ",") {
+		if strings.TrimSpace(directive) == "no-store" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isFresh reports whether resp's Date header plus its Cache-Control
+// max-age directive is still in the future, meaning it can be served as
+// is instead of revalidated against the origin.
+func isFresh(resp *http.Response) bool {
+	maxAge, ok := parseMaxAge(resp.Header.Get(headerCacheControl))
+	if !ok {
+		return false
+	}
+
+	date, err := http.ParseTime(resp.Header.Get(headerDate))
+	if err != nil {
+		return false
+	}
+
+	return time.Now().Before(date.Add(maxAge))
+}
+
+func parseMaxAge(cacheControl string) (time.Duration, bool) {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		seconds, ok := strings.CutPrefix(strings.TrimSpace(directive), "max-age=")
+		if !ok {
+			continue
+		}
+
+		n, err := strconv.Atoi(seconds)
+		if err != nil {
+			return 0, false
+		}
+
+		return time.Duration(n) * time.Second, true
+	}
+
+	return 0, false
+}
+
+// CachingClient is an HTTP client for the timestamp endpoint that
+// transparently reuses cached GET responses via a ResponseCache, only
+// hitting the server when the cached entry is missing or stale.
+type CachingClient struct {
+	*http.Client
+	Cache ResponseCache
+}
+
+func NewCachingClient(cache ResponseCache) *CachingClient {
+	return &CachingClient{
+		Client: &http.Client{Transport: &CachingTransport{Cache: cache}},
+		Cache:  cache,
+	}
+}
+
+// HTTPTimestampStore adapts a CachingClient to the TimestampStore
+// interface by talking to baseURL + "/timestamp" over HTTP, so Client
+// can reuse cached GET responses across Run invocations instead of
+// always hitting the store in-process.
+type HTTPTimestampStore struct {
+	client  *CachingClient
+	baseURL string
+}
+
+func NewHTTPTimestampStore(client *CachingClient, baseURL string) *HTTPTimestampStore {
+	return &HTTPTimestampStore{client: client, baseURL: baseURL}
+}
+
+// GetTimestamp retries briefly because Client.Run's goroutine can race
+// the server goroutine's Listen call at process startup; it gives up and
+// returns 0, matching an empty store's zero-value semantics, if the
+// server never becomes reachable.
+func (s *HTTPTimestampStore) GetTimestamp() int64 {
+	var timestamp int64
+
+	s.retry(func() error {
+		resp, err := s.client.Get(s.baseURL + "/timestamp")
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		parsed, err := strconv.ParseInt(strings.TrimSpace(string(body)), 10, 64)
+		if err != nil {
+			return err
+		}
+
+		timestamp = parsed
+		return nil
+	})
+
+	return timestamp
+}
+
+func (s *HTTPTimestampStore) StoreTimestamp(timestamp int64) {
+	s.retry(func() error {
+		resp, err := s.client.Post(s.baseURL+"/timestamp", contentTypeTextPlain, strings.NewReader(strconv.FormatInt(timestamp, 10)))
+		if err != nil {
+			return err
+		}
+
+		return resp.Body.Close()
+	})
+}
+
+func (s *HTTPTimestampStore) retry(fn func() error) {
+	const (
+		attempts = 10
+		backoff  = 50 * time.Millisecond
+	)
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err := fn(); err == nil {
+			return
+		}
+
+		time.Sleep(backoff)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmojzes/webservice/store"
+)
+
+func TestGetTimestampCacheHeaders(t *testing.T) {
+	timestamp := time.Now()
+	timestampStore := store.NewInMemory()
+	timestampStore.StoreTimestamp(timestamp.Unix())
+	server := NewTimestampServer(Options{Store: timestampStore})
+
+	t.Run("sets ETag, Last-Modified and Cache-Control", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTimestampRequest())
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		if response.Header().Get(headerETag) == "" {
+			t.Error("expected an ETag header, got none")
+		}
+		if response.Header().Get(headerLastModified) == "" {
+			t.Error("expected a Last-Modified header, got none")
+		}
+		if response.Header().Get(headerCacheControl) == "" {
+			t.Error("expected a Cache-Control header, got none")
+		}
+	})
+
+	t.Run("returns 304 when If-None-Match matches", func(t *testing.T) {
+		first := httptest.NewRecorder()
+		server.ServeHTTP(first, newGetTimestampRequest())
+
+		request := newGetTimestampRequest()
+		request.Header.Set(headerIfNoneMatch, first.Header().Get(headerETag))
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotModified)
+	})
+
+	t.Run("returns 304 when If-Modified-Since is not before Last-Modified", func(t *testing.T) {
+		request := newGetTimestampRequest()
+		request.Header.Set(headerIfModifiedSince, timestamp.Add(time.Second).UTC().Format(http.TimeFormat))
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotModified)
+	})
+
+	t.Run("returns 200 when validators are stale", func(t *testing.T) {
+		request := newGetTimestampRequest()
+		request.Header.Set(headerIfNoneMatch, `"stale"`)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+}
+
+func TestCachingClient(t *testing.T) {
+	hits := 0
+	timestamp := time.Now().Unix()
+	timestampStore := store.NewInMemory()
+	timestampStore.StoreTimestamp(timestamp)
+
+	upstream := NewTimestampServer(Options{Store: timestampStore})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		upstream.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := NewCachingClient(NewMemoryResponseCache())
+
+	t.Run("miss fetches from upstream and stores the response", func(t *testing.T) {
+		hits = 0
+
+		resp, err := client.Get(server.URL + "/timestamp")
+		assertNoError(t, err)
+		defer resp.Body.Close()
+
+		assertStatus(t, resp.StatusCode, http.StatusOK)
+		assertResponseBodyReader(t, resp, timestamp)
+
+		if hits != 1 {
+			t.Errorf("got %d upstream hits want %d", hits, 1)
+		}
+	})
+
+	t.Run("fresh cache hit is served without contacting upstream", func(t *testing.T) {
+		resp, err := client.Get(server.URL + "/timestamp")
+		assertNoError(t, err)
+		defer resp.Body.Close()
+
+		assertStatus(t, resp.StatusCode, http.StatusOK)
+		assertResponseBodyReader(t, resp, timestamp)
+
+		if hits != 1 {
+			t.Errorf("expected the fresh cache entry to be served without a network round-trip, got %d hits want %d", hits, 1)
+		}
+	})
+
+	t.Run("stale cache entry revalidates against upstream", func(t *testing.T) {
+		cacheKey := server.URL + "/timestamp" + "\nAccept: "
+
+		cachedBytes, ok := client.Cache.Get(cacheKey)
+		if !ok {
+			t.Fatal("expected a cached response from the prior Get")
+		}
+
+		getRequest, err := http.NewRequest(http.MethodGet, server.URL+"/timestamp", nil)
+		assertNoError(t, err)
+
+		cachedResp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(cachedBytes)), getRequest)
+		assertNoError(t, err)
+		cachedResp.Header.Set(headerDate, time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+
+		staleBytes, err := httputil.DumpResponse(cachedResp, true)
+		assertNoError(t, err)
+		client.Cache.Set(cacheKey, staleBytes)
+
+		resp, err := client.Get(server.URL + "/timestamp")
+		assertNoError(t, err)
+		defer resp.Body.Close()
+
+		assertStatus(t, resp.StatusCode, http.StatusOK)
+		assertResponseBodyReader(t, resp, timestamp)
+
+		if hits != 2 {
+			t.Errorf("expected a stale entry to revalidate against upstream, got %d hits want %d", hits, 2)
+		}
+	})
+}
+
+func TestClientReusesCachedGetAcrossRuns(t *testing.T) {
+	hits := 0
+	timestampStore := store.NewInMemory()
+
+	upstream := NewTimestampServer(Options{Store: timestampStore})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		upstream.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	httpStore := NewHTTPTimestampStore(NewCachingClient(NewMemoryResponseCache()), server.URL)
+
+	var out strings.Builder
+	client := Client{store: httpStore, out: &out}
+
+	client.Run(time.Now().Unix())
+	if hits != 2 {
+		t.Fatalf("first Run: got %d upstream hits want %d (one POST, one GET miss)", hits, 2)
+	}
+
+	client.Run(time.Now().Unix())
+	if hits != 3 {
+		t.Errorf("second Run: got %d upstream hits want %d (one POST; the GET is still fresh and served from cache)", hits, 3)
+	}
+}
+
+func assertResponseBodyReader(t testing.TB, resp *http.Response, want int64) {
+	t.Helper()
+
+	buf := make([]byte, 32)
+	n, _ := resp.Body.Read(buf)
+
+	got, err := strconv.ParseInt(string(buf[:n]), 10, 0)
+	if err != nil {
+		t.Fatalf("failed to parse response body to int64, %v", err)
+	}
+
+	if got != want {
+		t.Errorf("haven't got the expected timestamp, got %d want %d", got, want)
+	}
+}
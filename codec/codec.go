@@ -0,0 +1,96 @@
+// Package codec implements per-content-type encoding and decoding of the
+// timestamp server's single int64 value, so TimestampServer can negotiate
+// a representation from the client's Accept/Content-Type header instead
+// of always writing a bare text/plain integer. Codecs register
+// themselves by MIME type, mirroring how package store registers named
+// backends.
+package codec
+
+import (
+	"io"
+	"strings"
+	"sync"
+)
+
+// Content types every built-in codec is registered under.
+const (
+	ContentTypeText     = "text/plain"
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// Codec converts a timestamp to and from one wire representation.
+type Codec interface {
+	// ContentType is the MIME type this codec produces, and the one it is
+	// registered and looked up under.
+	ContentType() string
+	Encode(w io.Writer, timestamp int64) error
+	Decode(r io.Reader) (int64, error)
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Codec{}
+)
+
+// Register adds a Codec under its ContentType. It is meant to be called
+// from an init() function and panics on a duplicate registration,
+// mirroring store.Register.
+func Register(c Codec) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	contentType := c.ContentType()
+
+	if _, exists := registry[contentType]; exists {
+		panic("codec: Register called twice for content type " + contentType)
+	}
+
+	registry[contentType] = c
+}
+
+// Lookup returns the codec registered for contentType, if any.
+func Lookup(contentType string) (Codec, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	c, ok := registry[contentType]
+	return c, ok
+}
+
+// Negotiate picks the first registered codec among accept's comma
+// separated media types, ignoring any parameters such as a q value, and
+// falls back to the text codec when accept is empty or "*/*" so clients
+// that never set an Accept header keep getting a bare integer.
+func Negotiate(accept string) (Codec, bool) {
+	if accept == "" {
+		return Lookup(ContentTypeText)
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+
+		if mediaType == "*/*" {
+			return Lookup(ContentTypeText)
+		}
+
+		if c, ok := Lookup(mediaType); ok {
+			return c, true
+		}
+	}
+
+	return nil, false
+}
+
+// Names returns the registered content types.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+
+	return names
+}
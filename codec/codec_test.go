@@ -0,0 +1,135 @@
+package codec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	cases := []struct {
+		name      string
+		codec     Codec
+		timestamp int64
+		wantBody  string // substring the encoded body must contain
+	}{
+		{name: "text", codec: Text{}, timestamp: 1699999999, wantBody: "1699999999"},
+		{name: "text negative", codec: Text{}, timestamp: -5, wantBody: "-5"},
+		{name: "json", codec: JSON{}, timestamp: 1699999999, wantBody: `"timestamp":1699999999`},
+		{name: "json iso", codec: JSON{}, timestamp: 1699999999, wantBody: "2023-11-14T22:13:19Z"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			if err := tc.codec.Encode(&buf, tc.timestamp); err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+
+			if !strings.Contains(buf.String(), tc.wantBody) {
+				t.Errorf("encoded body %q does not contain %q", buf.String(), tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestCodecDecodeRoundTrip(t *testing.T) {
+	for _, c := range []Codec{Text{}, JSON{}, Protobuf{}} {
+		t.Run(c.ContentType(), func(t *testing.T) {
+			for _, want := range []int64{0, 1, 1699999999, -42} {
+				var buf bytes.Buffer
+
+				if err := c.Encode(&buf, want); err != nil {
+					t.Fatalf("Encode(%d): %v", want, err)
+				}
+
+				got, err := c.Decode(&buf)
+				if err != nil {
+					t.Fatalf("Decode: %v", err)
+				}
+
+				if got != want {
+					t.Errorf("round trip got %d want %d", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCodecDecodeMalformed(t *testing.T) {
+	cases := []struct {
+		name  string
+		codec Codec
+		body  string
+	}{
+		{name: "text empty", codec: Text{}, body: ""},
+		{name: "text not a number", codec: Text{}, body: "not-a-number"},
+		{name: "text overflows int64", codec: Text{}, body: strings.Repeat("9", 21)},
+		{name: "text longer than the fixed buffer", codec: Text{}, body: strings.Repeat("1", 25)},
+		{name: "json not json", codec: JSON{}, body: "not json"},
+		{name: "protobuf missing tag", codec: Protobuf{}, body: ""},
+		{name: "protobuf wrong tag", codec: Protobuf{}, body: string([]byte{0x10, 0x01})},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.codec.Decode(strings.NewReader(tc.body)); err == nil {
+				t.Errorf("Decode(%q) succeeded, want an error", tc.body)
+			}
+		})
+	}
+}
+
+func TestISOEncode(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := (ISO{}).Encode(&buf, 1699999999); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	want := "2023-11-14T22:13:19Z"
+	if buf.String() != want {
+		t.Errorf("got %q want %q", buf.String(), want)
+	}
+
+	if _, err := (ISO{}).Decode(strings.NewReader(want)); err == nil {
+		t.Error("Decode succeeded, want an error: ISO is encode-only")
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		name         string
+		accept       string
+		wantContType string
+		wantOK       bool
+	}{
+		{name: "empty defaults to text", accept: "", wantContType: ContentTypeText, wantOK: true},
+		{name: "wildcard defaults to text", accept: "*/*", wantContType: ContentTypeText, wantOK: true},
+		{name: "json", accept: "application/json", wantContType: ContentTypeJSON, wantOK: true},
+		{name: "protobuf", accept: "application/x-protobuf", wantContType: ContentTypeProtobuf, wantOK: true},
+		{name: "q values and multiple types", accept: "application/xml;q=0.9, application/json;q=0.8", wantContType: ContentTypeJSON, wantOK: true},
+		{name: "no match", accept: "application/xml", wantOK: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, ok := Negotiate(tc.accept)
+
+			if ok != tc.wantOK {
+				t.Fatalf("Negotiate(%q) ok = %v, want %v", tc.accept, ok, tc.wantOK)
+			}
+
+			if ok && c.ContentType() != tc.wantContType {
+				t.Errorf("Negotiate(%q) = %q, want %q", tc.accept, c.ContentType(), tc.wantContType)
+			}
+		})
+	}
+}
+
+func TestLookupUnknown(t *testing.T) {
+	if _, ok := Lookup("application/nonexistent"); ok {
+		t.Error("expected no codec for an unregistered content type")
+	}
+}
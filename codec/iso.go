@@ -0,0 +1,24 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// ISO writes the timestamp as an RFC 3339 string instead of a raw
+// integer. Unlike the other codecs it is not registered in the MIME
+// registry: it's reached only through the server's ?format=iso override
+// for browsers, never through Accept negotiation.
+type ISO struct{}
+
+func (ISO) ContentType() string { return ContentTypeText }
+
+func (ISO) Encode(w io.Writer, timestamp int64) error {
+	_, err := io.WriteString(w, time.Unix(timestamp, 0).UTC().Format(time.RFC3339))
+	return err
+}
+
+func (ISO) Decode(io.Reader) (int64, error) {
+	return 0, fmt.Errorf("codec: iso format does not support decoding")
+}
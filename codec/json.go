@@ -0,0 +1,48 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+func init() {
+	Register(JSON{})
+}
+
+// jsonTimestamp is the wire shape JSON encodes and decodes, e.g.
+// {"timestamp":1699999999,"iso":"2023-11-14T22:13:19Z"}.
+type jsonTimestamp struct {
+	Timestamp int64  `json:"timestamp"`
+	ISO       string `json:"iso"`
+}
+
+// JSON encodes a timestamp alongside its RFC 3339 rendering. Decode only
+// looks at the timestamp field; iso is accepted but ignored since the
+// unix value is authoritative.
+type JSON struct{}
+
+func (JSON) ContentType() string { return ContentTypeJSON }
+
+func (JSON) Encode(w io.Writer, timestamp int64) error {
+	return json.NewEncoder(w).Encode(jsonTimestamp{
+		Timestamp: timestamp,
+		ISO:       time.Unix(timestamp, 0).UTC().Format(time.RFC3339),
+	})
+}
+
+// maxJSONBodyLen bounds how much of the request body Decode will read,
+// so a client can't force the server to buffer an unbounded body just
+// to find a single int64 field.
+const maxJSONBodyLen = 4 << 10
+
+func (JSON) Decode(r io.Reader) (int64, error) {
+	var body jsonTimestamp
+
+	if err := json.NewDecoder(io.LimitReader(r, maxJSONBodyLen)).Decode(&body); err != nil {
+		return 0, fmt.Errorf("codec: decode json: %w", err)
+	}
+
+	return body.Timestamp, nil
+}
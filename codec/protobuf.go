@@ -0,0 +1,60 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+func init() {
+	Register(Protobuf{})
+}
+
+// timestampFieldTag is the wire tag for Timestamp.timestamp in
+// timestamp.proto: field number 1, varint wire type 0, packed as
+// (field<<3)|wiretype.
+const timestampFieldTag = 1<<3 | 0
+
+// maxProtobufBodyLen bounds the read in Decode: a tag byte plus the
+// 10-byte varint a 64-bit value can expand to.
+const maxProtobufBodyLen = 1 + binary.MaxVarintLen64
+
+// Protobuf encodes/decodes the single-field Timestamp message defined in
+// timestamp.proto by hand, using the standard protobuf varint wire
+// format, rather than depending on protoc-gen-go and its runtime for one
+// int64 field.
+type Protobuf struct{}
+
+func (Protobuf) ContentType() string { return ContentTypeProtobuf }
+
+func (Protobuf) Encode(w io.Writer, timestamp int64) error {
+	var buf [maxProtobufBodyLen]byte
+
+	buf[0] = timestampFieldTag
+	n := binary.PutUvarint(buf[1:], uint64(timestamp))
+
+	_, err := w.Write(buf[:1+n])
+	return err
+}
+
+func (Protobuf) Decode(r io.Reader) (int64, error) {
+	var buf [maxProtobufBodyLen]byte
+
+	n, err := ReadFull(r, buf[:])
+	if err != nil {
+		return 0, fmt.Errorf("codec: read protobuf body: %w", err)
+	}
+
+	body := buf[:n]
+
+	if len(body) == 0 || body[0] != timestampFieldTag {
+		return 0, fmt.Errorf("codec: missing Timestamp.timestamp field (tag %#x)", timestampFieldTag)
+	}
+
+	value, varintLen := binary.Uvarint(body[1:])
+	if varintLen <= 0 {
+		return 0, fmt.Errorf("codec: malformed varint in Timestamp.timestamp")
+	}
+
+	return int64(value), nil
+}
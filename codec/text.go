@@ -0,0 +1,119 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+func init() {
+	Register(Text{})
+}
+
+// maxTimestampBodyLen bounds the fixed stack buffer Text.Decode reads
+// into: an int64 is at most 20 bytes as decimal text (sign plus 19
+// digits).
+const maxTimestampBodyLen = 20
+
+// Text is the original bare-integer codec, kept for back-compat with
+// clients that never send an Accept/Content-Type header.
+type Text struct{}
+
+func (Text) ContentType() string { return ContentTypeText }
+
+func (Text) Encode(w io.Writer, timestamp int64) error {
+	_, err := io.WriteString(w, strconv.FormatInt(timestamp, 10))
+	return err
+}
+
+func (Text) Decode(r io.Reader) (int64, error) {
+	// buf is one byte larger than maxTimestampBodyLen so a body that fills
+	// it can be told apart from one that overflows it: ReadFull reports a
+	// full read whether the body was exactly maxTimestampBodyLen bytes or
+	// longer, and only reading past that boundary tells the two apart.
+	var buf [maxTimestampBodyLen + 1]byte
+
+	n, err := ReadFull(r, buf[:])
+	if err != nil {
+		return 0, err
+	}
+
+	if n > maxTimestampBodyLen {
+		return 0, fmt.Errorf("request body exceeds %d bytes", maxTimestampBodyLen)
+	}
+
+	return ParseInt64(buf[:n])
+}
+
+// ReadFull reads from r into buf until buf is full or r is exhausted,
+// unlike io.ReadFull it treats a short read followed by EOF as success
+// rather than io.ErrUnexpectedEOF, since a timestamp body is almost
+// always shorter than buf.
+func ReadFull(r io.Reader, buf []byte) (int, error) {
+	total := 0
+
+	for total < len(buf) {
+		n, err := r.Read(buf[total:])
+		total += n
+
+		if err != nil {
+			if err == io.EOF {
+				return total, nil
+			}
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// ParseInt64 parses a signed decimal integer from b without allocating,
+// unlike strconv.ParseInt(string(b), ...) which must copy b into a string.
+func ParseInt64(b []byte) (int64, error) {
+	if len(b) == 0 {
+		return 0, fmt.Errorf("empty request body")
+	}
+
+	neg := false
+	i := 0
+
+	if b[0] == '-' {
+		neg = true
+		i = 1
+	}
+
+	if i >= len(b) {
+		return 0, fmt.Errorf("invalid integer %q", b)
+	}
+
+	// limit is the largest magnitude n may reach: math.MaxInt64 for
+	// positive numbers, one more than that for negative numbers, since
+	// int64's range is asymmetric (-9223372036854775808 has no positive
+	// counterpart).
+	limit := uint64(math.MaxInt64)
+	if neg {
+		limit++
+	}
+
+	var n uint64
+	for ; i < len(b); i++ {
+		c := b[i]
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid integer %q", b)
+		}
+
+		d := uint64(c - '0')
+		if n > (limit-d)/10 {
+			return 0, fmt.Errorf("integer %q overflows int64", b)
+		}
+
+		n = n*10 + d
+	}
+
+	if neg {
+		return -int64(n), nil
+	}
+
+	return int64(n), nil
+}
@@ -0,0 +1,99 @@
+//go:build fasthttp
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/tmojzes/webservice/store"
+)
+
+// BenchmarkFastGetTimestamp and BenchmarkFastStoreTimestamp mirror
+// BenchmarkGetTimestamp/BenchmarkStoreTimestamp in bench_test.go, but
+// drive FastTimestampServer directly through a fasthttp.RequestCtx, the
+// way fasthttp's own allocation benchmarks exercise RequestHandler, so
+// the two implementations' allocation profiles are comparable.
+
+func BenchmarkFastGetTimestamp(b *testing.B) {
+	timestampStore := store.NewInMemory()
+	timestampStore.StoreTimestamp(time.Now().Unix())
+	server := NewFastTimestampServer(Options{Store: timestampStore})
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("/timestamp")
+	ctx.Request.Header.SetMethod(fasthttp.MethodGet)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ctx.Response.Reset()
+		server.Handler(&ctx)
+	}
+}
+
+func TestFastGetTimestampAllocs(t *testing.T) {
+	timestampStore := store.NewInMemory()
+	timestampStore.StoreTimestamp(time.Now().Unix())
+	server := NewFastTimestampServer(Options{Store: timestampStore})
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("/timestamp")
+	ctx.Request.Header.SetMethod(fasthttp.MethodGet)
+
+	const maxAllocsPerRun = 12
+
+	allocs := testing.AllocsPerRun(100, func() {
+		ctx.Response.Reset()
+		server.Handler(&ctx)
+	})
+
+	if allocs > maxAllocsPerRun {
+		t.Errorf("fasthttp GET /timestamp allocates %.0f times per call, want <= %d", allocs, maxAllocsPerRun)
+	}
+}
+
+func BenchmarkFastStoreTimestamp(b *testing.B) {
+	timestampStore := store.NewInMemory()
+	server := NewFastTimestampServer(Options{Store: timestampStore})
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("/timestamp")
+	ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+	ctx.Request.SetBodyString("1699999999")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ctx.Response.Reset()
+		server.Handler(&ctx)
+	}
+}
+
+func TestFastStoreTimestampAllocs(t *testing.T) {
+	timestampStore := store.NewInMemory()
+	server := NewFastTimestampServer(Options{Store: timestampStore})
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("/timestamp")
+	ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+	ctx.Request.SetBodyString("1699999999")
+
+	// 8 accounts for store.InMemory's history bookkeeping (the append
+	// under its mutex, amortized across repeated calls) on top of the
+	// fasthttp request parsing path.
+	const maxAllocsPerRun = 8
+
+	allocs := testing.AllocsPerRun(100, func() {
+		ctx.Response.Reset()
+		server.Handler(&ctx)
+	})
+
+	if allocs > maxAllocsPerRun {
+		t.Errorf("fasthttp POST /timestamp allocates %.0f times per call, want <= %d", allocs, maxAllocsPerRun)
+	}
+}
@@ -0,0 +1,87 @@
+//go:build fasthttp
+
+package main
+
+import (
+	"strconv"
+
+	"github.com/tmojzes/webservice/codec"
+	"github.com/valyala/fasthttp"
+)
+
+func init() {
+	startFastHTTPServer = runFastHTTPServer
+}
+
+// FastTimestampServer is an alternative to TimestampServer built on
+// fasthttp instead of net/http, sharing the same TimestampStore so its
+// allocation profile can be compared directly against the net/http
+// implementation in BenchmarkFastGetTimestamp/BenchmarkFastStoreTimestamp.
+type FastTimestampServer struct {
+	store TimestampStore
+}
+
+func NewFastTimestampServer(opts Options) *FastTimestampServer {
+	return &FastTimestampServer{store: opts.Store}
+}
+
+func (t *FastTimestampServer) Handler(ctx *fasthttp.RequestCtx) {
+	if string(ctx.Path()) != "/timestamp" {
+		ctx.SetContentType(contentTypeTextPlain)
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(notFoundMessage)
+		return
+	}
+
+	switch string(ctx.Method()) {
+	case fasthttp.MethodGet:
+		t.getTimestamp(ctx)
+	case fasthttp.MethodPost:
+		t.storeTimestamp(ctx)
+	case fasthttp.MethodDelete:
+		t.deleteTimestamp(ctx)
+	default:
+		ctx.SetStatusCode(fasthttp.StatusMethodNotAllowed)
+	}
+}
+
+func (t *FastTimestampServer) getTimestamp(ctx *fasthttp.RequestCtx) {
+	ctx.SetContentType(contentTypeTextPlain)
+
+	timestamp := t.store.GetTimestamp()
+	if timestamp == 0 {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+	}
+
+	ctx.SetBodyString(strconv.FormatInt(timestamp, 10))
+}
+
+func (t *FastTimestampServer) storeTimestamp(ctx *fasthttp.RequestCtx) {
+	timestamp, err := codec.ParseInt64(ctx.PostBody())
+	if err != nil {
+		ctx.Error("Invalid request body", fasthttp.StatusBadRequest)
+		return
+	}
+
+	t.store.StoreTimestamp(timestamp)
+
+	ctx.SetStatusCode(fasthttp.StatusAccepted)
+}
+
+func (t *FastTimestampServer) deleteTimestamp(ctx *fasthttp.RequestCtx) {
+	deletableStore, ok := t.store.(DeletableStore)
+	if !ok {
+		ctx.Error("store does not support deletion", fasthttp.StatusNotImplemented)
+		return
+	}
+
+	deletableStore.Delete()
+
+	ctx.SetStatusCode(fasthttp.StatusNoContent)
+}
+
+func runFastHTTPServer(cfg serverConfig, store TimestampStore) error {
+	server := NewFastTimestampServer(Options{Store: store})
+
+	return fasthttp.ListenAndServe(cfg.Addr, server.Handler)
+}
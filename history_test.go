@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tmojzes/webservice/store"
+)
+
+func TestTimestampServerHistoryAndDelete(t *testing.T) {
+	s, err := store.NewFile(t.TempDir())
+	if err != nil {
+		t.Fatalf("store.NewFile: %v", err)
+	}
+
+	server := NewTimestampServer(Options{Store: s})
+
+	for _, timestamp := range []int64{1, 2, 3} {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPostTimestampRequest(timestamp))
+		assertStatus(t, response.Code, http.StatusAccepted)
+	}
+
+	t.Run("GET with limit returns history as JSON", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/timestamp?limit=2", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertContentType(t, response.Header(), contentTypeJSON)
+
+		var got []int64
+		if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+			t.Fatalf("failed to decode history response, %v", err)
+		}
+
+		assertHistory(t, got, []int64{2, 3})
+	})
+
+	t.Run("DELETE clears the store", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/timestamp", nil))
+
+		assertStatus(t, response.Code, http.StatusNoContent)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTimestampRequest())
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+}
+
+func assertHistory(t testing.TB, got, want []int64) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got history %v want %v", got, want)
+	}
+
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("got history %v want %v", got, want)
+			return
+		}
+	}
+}
@@ -1,20 +1,27 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
+
+	"github.com/tmojzes/webservice/codec"
+	"github.com/tmojzes/webservice/store"
 )
 
 const (
 	notFoundMessage      = "404 - Page not found"
 	contentTypeTextPlain = "text/plain"
+	contentTypeJSON      = "application/json"
 )
 
 type Client struct {
@@ -35,33 +42,23 @@ type TimestampStore interface {
 	StoreTimestamp(timestamp int64)
 }
 
-type InMemoryTimestampStore struct {
-	timestamp atomic.Value
+// HistoryStore is implemented by stores that can report prior values, so
+// GET /timestamp?limit=N can be served without every backend supporting it.
+type HistoryStore interface {
+	TimestampStore
+	History(limit int) []int64
 }
 
-func NewInMemoryTimestampStore() *InMemoryTimestampStore {
-	store := &InMemoryTimestampStore{}
-
-	store.timestamp.Store(time.Time{})
-	return store
+// DeletableStore is implemented by stores that support clearing their
+// state, backing the DELETE /timestamp handler.
+type DeletableStore interface {
+	TimestampStore
+	Delete()
 }
 
-func (i *InMemoryTimestampStore) GetTimestamp() int64 {
-	t := i.timestamp.Load()
-
-	timestamp := t.(time.Time)
-
-	if timestamp.IsZero() {
-		return 0
-	}
-
-	return timestamp.Unix()
-}
-
-func (i *InMemoryTimestampStore) StoreTimestamp(timestamp int64) {
-	t := time.Unix(timestamp, 0)
-
-	i.timestamp.Store(t)
+// Options configures a TimestampServer. Store is the only required field.
+type Options struct {
+	Store TimestampStore
 }
 
 type TimestampServer struct {
@@ -69,15 +66,16 @@ type TimestampServer struct {
 	http.Handler
 }
 
-func NewTimestampServer(store TimestampStore) *TimestampServer {
+func NewTimestampServer(opts Options) *TimestampServer {
 	t := new(TimestampServer)
 
-	t.store = store
+	t.store = opts.Store
 
 	router := http.NewServeMux()
 	router.HandleFunc("/", t.notFoundHandler())
 	router.HandleFunc("GET /timestamp", t.getTimestamp())
 	router.HandleFunc("POST /timestamp", t.storeTimestamp())
+	router.HandleFunc("DELETE /timestamp", t.deleteTimestamp())
 
 	t.Handler = router
 
@@ -85,40 +83,124 @@ func NewTimestampServer(store TimestampStore) *TimestampServer {
 }
 
 func (t *TimestampServer) getTimestamp() http.HandlerFunc {
-	return func(w http.ResponseWriter, _ *http.Request) {
-		w.Header().Set("Content-Type", contentTypeTextPlain)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+			t.getHistory(w, r, limitParam)
+			return
+		}
+
+		// variant identifies the representation for etagFor: it's distinct
+		// from c.ContentType() because the ?format=iso override shares
+		// ContentTypeText with the default Text codec but must not share
+		// its validator.
+		var c codec.Codec
+		var variant string
+
+		if r.URL.Query().Get("format") == "iso" {
+			c = codec.ISO{}
+			variant = "iso"
+		} else {
+			var ok bool
+			c, ok = codec.Negotiate(r.Header.Get("Accept"))
+			if !ok {
+				http.Error(w, "Not Acceptable", http.StatusNotAcceptable)
+				return
+			}
+			variant = c.ContentType()
+		}
+
+		w.Header().Set("Content-Type", c.ContentType())
 
 		timestamp := t.store.GetTimestamp()
 
 		if timestamp == 0 {
 			w.WriteHeader(http.StatusNotFound)
+			c.Encode(w, timestamp)
+			return
+		}
+
+		if lastModifiedStore, ok := t.store.(LastModifiedStore); ok {
+			notModified := writeCacheHeaders(w, r, lastModifiedStore.LastModified(), etagFor(timestamp, variant))
+			if notModified {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
 		}
 
-		fmt.Fprint(w, timestamp)
+		c.Encode(w, timestamp)
+	}
+}
+
+func (t *TimestampServer) getHistory(w http.ResponseWriter, _ *http.Request, limitParam string) {
+	w.Header().Set("Content-Type", contentTypeJSON)
+
+	historyStore, ok := t.store.(HistoryStore)
+	if !ok {
+		http.Error(w, "store does not support history", http.StatusNotImplemented)
+		return
+	}
+
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(historyStore.History(limit))
+}
+
+func (t *TimestampServer) deleteTimestamp() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		deletableStore, ok := t.store.(DeletableStore)
+		if !ok {
+			http.Error(w, "store does not support deletion", http.StatusNotImplemented)
+			return
+		}
+
+		deletableStore.Delete()
+
+		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
 func (t *TimestampServer) storeTimestamp() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Body != nil {
+		if r.Body == nil {
+			return
+		}
 
-			bodyBytes, err := io.ReadAll(r.Body)
-			if err != nil {
-				http.Error(w, "Invalid request body", http.StatusBadRequest)
-				return
-			}
+		c, ok := codec.Lookup(mediaType(r.Header.Get("Content-Type")))
+		if !ok {
+			http.Error(w, "Unsupported Content-Type", http.StatusUnsupportedMediaType)
+			return
+		}
 
-			timestamp, err := strconv.ParseInt(string(bodyBytes), 10, 0)
-			if err != nil {
-				http.Error(w, "Invalid request body", http.StatusBadRequest)
-				return
-			}
+		timestamp, err := c.Decode(r.Body)
+		if err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
 
-			t.store.StoreTimestamp(timestamp)
+		t.store.StoreTimestamp(timestamp)
 
-			w.WriteHeader(http.StatusAccepted)
-		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// mediaType returns the base media type from a Content-Type header,
+// defaulting to codec.ContentTypeText so clients that never set the
+// header, the original API contract, keep working unchanged.
+func mediaType(contentType string) string {
+	if contentType == "" {
+		return codec.ContentTypeText
 	}
+
+	base, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+
+	return base
 }
 
 func (t *TimestampServer) notFoundHandler() http.HandlerFunc {
@@ -132,9 +214,23 @@ func (t *TimestampServer) notFoundHandler() http.HandlerFunc {
 }
 
 func main() {
-	store := NewInMemoryTimestampStore()
-	server := NewTimestampServer(store)
-	port := ":8888"
+	mode := flag.String("mode", "", "server mode: h1, h1-tls, h2, h2c or fasthttp (default h1, or $SERVER_MODE)")
+	addr := flag.String("addr", "", "listen address (default :8888, or $SERVER_ADDR)")
+	certFile := flag.String("cert", "", "TLS certificate file, required for h1-tls and h2 ($SERVER_CERT)")
+	keyFile := flag.String("key", "", "TLS key file, required for h1-tls and h2 ($SERVER_KEY)")
+	storeBackend := flag.String("store", "memory", "timestamp store backend: "+fmt.Sprint(store.Names()))
+	storeDataDir := flag.String("store-data-dir", ".", "data directory for stores that persist to disk")
+	flag.Parse()
+
+	cfg := serverConfigFromEnv(*mode, *addr, *certFile, *keyFile)
+	if cfg.Addr == "" {
+		cfg.Addr = ":8888"
+	}
+
+	timestampStore, err := store.Open(*storeBackend, *storeDataDir)
+	if err != nil {
+		log.Fatalf("could not open store %q, %v\n", *storeBackend, err)
+	}
 
 	var wg sync.WaitGroup
 
@@ -142,9 +238,25 @@ func main() {
 	go func() {
 		defer wg.Done()
 
-		log.Printf("http server listening on port %s\n", port)
-		if err := http.ListenAndServe(port, server); err != nil {
-			log.Fatalf("could not listen on port %s, %v\n", port, err)
+		log.Printf("http server listening on %s in %s mode\n", cfg.Addr, cfg.Mode)
+
+		if cfg.Mode == modeFastHTTP {
+			if startFastHTTPServer == nil {
+				log.Fatalf("server mode %q requires building with -tags fasthttp\n", cfg.Mode)
+			}
+			if err := startFastHTTPServer(cfg, timestampStore); err != nil {
+				log.Fatalf("could not listen on %s, %v\n", cfg.Addr, err)
+			}
+			return
+		}
+
+		httpServer, err := newHTTPServer(cfg, NewTimestampServer(Options{Store: timestampStore}))
+		if err != nil {
+			log.Fatalf("could not configure server, %v\n", err)
+		}
+
+		if err := listenAndServe(cfg, httpServer); err != nil {
+			log.Fatalf("could not listen on %s, %v\n", cfg.Addr, err)
 		}
 	}()
 
@@ -152,9 +264,34 @@ func main() {
 	go func() {
 		defer wg.Done()
 
-		client := Client{store: store, out: os.Stdout}
+		client := Client{store: clientStore(cfg, timestampStore), out: os.Stdout}
 		client.Run(time.Now().Unix())
 	}()
 
 	wg.Wait()
 }
+
+// clientStore picks what the built-in Client talks to. For the cleartext
+// HTTP modes it's an HTTPTimestampStore wrapping a CachingClient, pointed
+// at the server this same process just started, so repeated Run calls
+// reuse a cached GET instead of hitting timestampStore directly. TLS and
+// fasthttp modes fall back to timestampStore, since a plain http.Client
+// can't dial them without extra certificate or transport configuration.
+func clientStore(cfg serverConfig, timestampStore TimestampStore) TimestampStore {
+	switch cfg.Mode {
+	case modeH1, modeH2C:
+		return NewHTTPTimestampStore(NewCachingClient(NewMemoryResponseCache()), "http://"+loopbackAddr(cfg.Addr))
+	default:
+		return timestampStore
+	}
+}
+
+// loopbackAddr turns a listen address that only specifies a port, such as
+// ":8888", into one a client can dial, "127.0.0.1:8888".
+func loopbackAddr(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "127.0.0.1" + addr
+	}
+
+	return addr
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/tmojzes/webservice/store"
 )
 
 type StubTimestampStore struct {
@@ -32,7 +34,7 @@ func TestTimestampServerGet(t *testing.T) {
 	timestamp := time.Now()
 
 	store := StubTimestampStore{timestamp: timestamp, storeCalls: nil}
-	server := NewTimestampServer(&store)
+	server := NewTimestampServer(Options{Store: &store})
 
 	t.Run("get content type", func(t *testing.T) {
 		request := newGetTimestampRequest()
@@ -60,7 +62,7 @@ func TestTimestampServerGet(t *testing.T) {
 		response := httptest.NewRecorder()
 
 		emptyStore := StubTimestampStore{}
-		serverWithEmptyStore := NewTimestampServer(&emptyStore)
+		serverWithEmptyStore := NewTimestampServer(Options{Store: &emptyStore})
 		serverWithEmptyStore.ServeHTTP(response, request)
 
 		assertContentType(t, response.Header(), contentTypeTextPlain)
@@ -83,7 +85,7 @@ func TestTimestampServerGet(t *testing.T) {
 
 func TestTimestampServerStore(t *testing.T) {
 	store := StubTimestampStore{}
-	server := NewTimestampServer(&store)
+	server := NewTimestampServer(Options{Store: &store})
 
 	t.Run("test if it stores timestamp when POST", func(t *testing.T) {
 		timestamp := time.Now().Unix()
@@ -105,8 +107,8 @@ func TestTimestampServerStore(t *testing.T) {
 }
 
 func TestStoreAndRetrieve(t *testing.T) {
-	store := NewInMemoryTimestampStore()
-	server := NewTimestampServer(store)
+	timestampStore := store.NewInMemory()
+	server := NewTimestampServer(Options{Store: timestampStore})
 
 	t.Run("test store and retrieve", func(t *testing.T) {
 		timestamp := time.Now().Add(time.Second).Unix()
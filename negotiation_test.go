@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmojzes/webservice/codec"
+	"github.com/tmojzes/webservice/store"
+)
+
+func TestTimestampServerGetContentNegotiation(t *testing.T) {
+	timestamp := time.Now().Unix()
+
+	timestampStore := store.NewInMemory()
+	timestampStore.StoreTimestamp(timestamp)
+	server := NewTimestampServer(Options{Store: timestampStore})
+
+	cases := []struct {
+		name        string
+		accept      string
+		wantContent string
+	}{
+		{name: "no accept header defaults to text", accept: "", wantContent: codec.ContentTypeText},
+		{name: "text/plain", accept: "text/plain", wantContent: codec.ContentTypeText},
+		{name: "application/json", accept: "application/json", wantContent: codec.ContentTypeJSON},
+		{name: "application/x-protobuf", accept: "application/x-protobuf", wantContent: codec.ContentTypeProtobuf},
+		{name: "wildcard defaults to text", accept: "*/*", wantContent: codec.ContentTypeText},
+		{name: "first acceptable match wins", accept: "application/xml, application/json", wantContent: codec.ContentTypeJSON},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := newGetTimestampRequest()
+			if tc.accept != "" {
+				request.Header.Set("Accept", tc.accept)
+			}
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response.Code, http.StatusOK)
+			assertContentType(t, response.Header(), tc.wantContent)
+
+			c, ok := codec.Lookup(tc.wantContent)
+			if !ok {
+				t.Fatalf("no codec registered for %q", tc.wantContent)
+			}
+
+			got, err := c.Decode(bytes.NewReader(response.Body.Bytes()))
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+
+			assertTimestamp(t, got, timestamp)
+		})
+	}
+
+	t.Run("ETag and Vary differ per negotiated representation", func(t *testing.T) {
+		textResponse := httptest.NewRecorder()
+		textRequest := newGetTimestampRequest()
+		textRequest.Header.Set("Accept", "text/plain")
+		server.ServeHTTP(textResponse, textRequest)
+
+		jsonResponse := httptest.NewRecorder()
+		jsonRequest := newGetTimestampRequest()
+		jsonRequest.Header.Set("Accept", "application/json")
+		server.ServeHTTP(jsonResponse, jsonRequest)
+
+		if textResponse.Header().Get(headerVary) != "Accept" {
+			t.Errorf("expected Vary: Accept, got %q", textResponse.Header().Get(headerVary))
+		}
+
+		textETag := textResponse.Header().Get(headerETag)
+		jsonETag := jsonResponse.Header().Get(headerETag)
+		if textETag == jsonETag {
+			t.Errorf("text and json responses share an ETag (%q): a cache could serve one representation as the other", textETag)
+		}
+
+		// An If-None-Match validator from the text representation must not
+		// short-circuit a request negotiating JSON.
+		crossRequest := newGetTimestampRequest()
+		crossRequest.Header.Set("Accept", "application/json")
+		crossRequest.Header.Set(headerIfNoneMatch, textETag)
+		crossResponse := httptest.NewRecorder()
+		server.ServeHTTP(crossResponse, crossRequest)
+
+		assertStatus(t, crossResponse.Code, http.StatusOK)
+		assertContentType(t, crossResponse.Header(), codec.ContentTypeJSON)
+	})
+
+	t.Run("ETag differs between text/plain and format=iso despite sharing a content type", func(t *testing.T) {
+		plainResponse := httptest.NewRecorder()
+		server.ServeHTTP(plainResponse, newGetTimestampRequest())
+
+		isoRequest, _ := http.NewRequest(http.MethodGet, "/timestamp?format=iso", nil)
+		isoResponse := httptest.NewRecorder()
+		server.ServeHTTP(isoResponse, isoRequest)
+
+		plainETag := plainResponse.Header().Get(headerETag)
+		isoETag := isoResponse.Header().Get(headerETag)
+		if plainETag == isoETag {
+			t.Fatalf("text/plain and format=iso share an ETag (%q): a cache could serve one as the other", plainETag)
+		}
+
+		// Replaying the plain response's validator against the iso request
+		// must not short-circuit to 304 with the plain body's ETag.
+		staleCacheRequest, _ := http.NewRequest(http.MethodGet, "/timestamp?format=iso", nil)
+		staleCacheRequest.Header.Set(headerIfNoneMatch, plainETag)
+		staleCacheResponse := httptest.NewRecorder()
+		server.ServeHTTP(staleCacheResponse, staleCacheRequest)
+
+		assertStatus(t, staleCacheResponse.Code, http.StatusOK)
+
+		want := time.Unix(timestamp, 0).UTC().Format(time.RFC3339)
+		if staleCacheResponse.Body.String() != want {
+			t.Errorf("got body %q want %q", staleCacheResponse.Body.String(), want)
+		}
+	})
+
+	t.Run("unacceptable Accept header gets 406", func(t *testing.T) {
+		request := newGetTimestampRequest()
+		request.Header.Set("Accept", "application/xml")
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotAcceptable)
+	})
+
+	t.Run("format=iso overrides Accept", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/timestamp?format=iso", nil)
+		request.Header.Set("Accept", "application/json")
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		want := time.Unix(timestamp, 0).UTC().Format(time.RFC3339)
+		if response.Body.String() != want {
+			t.Errorf("got %q want %q", response.Body.String(), want)
+		}
+	})
+}
+
+func TestTimestampServerStoreContentNegotiation(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		body        func(timestamp int64) []byte
+	}{
+		{
+			name:        "no Content-Type defaults to text",
+			contentType: "",
+			body:        func(timestamp int64) []byte { return []byte(strconv.FormatInt(timestamp, 10)) },
+		},
+		{
+			name:        "text/plain",
+			contentType: codec.ContentTypeText,
+			body:        func(timestamp int64) []byte { return []byte(strconv.FormatInt(timestamp, 10)) },
+		},
+		{
+			name:        "application/json",
+			contentType: codec.ContentTypeJSON,
+			body: func(timestamp int64) []byte {
+				b, _ := json.Marshal(map[string]int64{"timestamp": timestamp})
+				return b
+			},
+		},
+		{
+			name:        "application/x-protobuf",
+			contentType: codec.ContentTypeProtobuf,
+			body: func(timestamp int64) []byte {
+				var buf bytes.Buffer
+				codec.Protobuf{}.Encode(&buf, timestamp)
+				return buf.Bytes()
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			timestampStore := store.NewInMemory()
+			server := NewTimestampServer(Options{Store: timestampStore})
+
+			timestamp := time.Now().Unix()
+			request, _ := http.NewRequest(http.MethodPost, "/timestamp", bytes.NewReader(tc.body(timestamp)))
+			if tc.contentType != "" {
+				request.Header.Set("Content-Type", tc.contentType)
+			}
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response.Code, http.StatusAccepted)
+			assertTimestamp(t, timestampStore.GetTimestamp(), timestamp)
+		})
+	}
+
+	t.Run("unknown Content-Type gets 415", func(t *testing.T) {
+		timestampStore := store.NewInMemory()
+		server := NewTimestampServer(Options{Store: timestampStore})
+
+		request, _ := http.NewRequest(http.MethodPost, "/timestamp", bytes.NewReader([]byte("1699999999")))
+		request.Header.Set("Content-Type", "application/xml")
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusUnsupportedMediaType)
+	})
+
+	malformedCases := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{name: "malformed text", contentType: codec.ContentTypeText, body: "not-a-number"},
+		{name: "oversized text overflows int64", contentType: codec.ContentTypeText, body: strings.Repeat("1", 25)},
+		{name: "malformed json", contentType: codec.ContentTypeJSON, body: "not json"},
+		{name: "malformed protobuf", contentType: codec.ContentTypeProtobuf, body: "\x10\x01"},
+	}
+
+	for _, tc := range malformedCases {
+		t.Run(tc.name, func(t *testing.T) {
+			timestampStore := store.NewInMemory()
+			server := NewTimestampServer(Options{Store: timestampStore})
+
+			request, _ := http.NewRequest(http.MethodPost, "/timestamp", bytes.NewReader([]byte(tc.body)))
+			request.Header.Set("Content-Type", tc.contentType)
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response.Code, http.StatusBadRequest)
+		})
+	}
+}
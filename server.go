@@ -0,0 +1,94 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// serverMode selects the protocol the process listens with.
+type serverMode string
+
+const (
+	modeH1       serverMode = "h1"       // HTTP/1.1 cleartext
+	modeH1TLS    serverMode = "h1-tls"   // HTTP/1.1 over TLS
+	modeH2       serverMode = "h2"       // HTTP/2 over TLS (ALPN negotiated)
+	modeH2C      serverMode = "h2c"      // HTTP/2 cleartext (prior-knowledge/upgrade)
+	modeFastHTTP serverMode = "fasthttp" // fasthttp-based server, requires the fasthttp build tag
+)
+
+// startFastHTTPServer is set by fasthttp_server.go's init() when the
+// binary is built with the fasthttp tag; nil otherwise, so modeFastHTTP
+// fails with a clear error instead of a missing-symbol build break.
+var startFastHTTPServer func(cfg serverConfig, store TimestampStore) error
+
+// serverConfig controls which protocol mode NewTimestampServer's handler
+// is served with, and where the TLS material comes from.
+type serverConfig struct {
+	Addr     string
+	Mode     serverMode
+	CertFile string
+	KeyFile  string
+}
+
+// serverConfigFromEnv builds a serverConfig from flags, falling back to
+// the SERVER_MODE, SERVER_ADDR, SERVER_CERT and SERVER_KEY env vars when
+// a flag was left at its zero value.
+func serverConfigFromEnv(mode, addr, certFile, keyFile string) serverConfig {
+	cfg := serverConfig{Mode: serverMode(mode), Addr: addr, CertFile: certFile, KeyFile: keyFile}
+
+	if cfg.Mode == "" {
+		cfg.Mode = serverMode(os.Getenv("SERVER_MODE"))
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = modeH1
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = os.Getenv("SERVER_ADDR")
+	}
+	if cfg.CertFile == "" {
+		cfg.CertFile = os.Getenv("SERVER_CERT")
+	}
+	if cfg.KeyFile == "" {
+		cfg.KeyFile = os.Getenv("SERVER_KEY")
+	}
+
+	return cfg
+}
+
+// newHTTPServer builds an *http.Server that serves handler in cfg.Mode,
+// wiring up ALPN for TLS modes and the h2c fallback handler for cleartext
+// HTTP/2 so the same TimestampServer.Handler serves every mode.
+func newHTTPServer(cfg serverConfig, handler http.Handler) (*http.Server, error) {
+	srv := &http.Server{Addr: cfg.Addr, Handler: handler}
+
+	switch cfg.Mode {
+	case modeH1:
+		// handler already set above.
+	case modeH1TLS:
+		srv.TLSConfig = &tls.Config{NextProtos: []string{"http/1.1"}}
+	case modeH2:
+		srv.TLSConfig = &tls.Config{NextProtos: []string{"h2", "http/1.1"}}
+	case modeH2C:
+		srv.Handler = h2c.NewHandler(handler, &http2.Server{})
+	default:
+		return nil, fmt.Errorf("unknown server mode %q", cfg.Mode)
+	}
+
+	return srv, nil
+}
+
+// listenAndServe starts srv according to cfg.Mode, using TLS when the mode
+// requires it.
+func listenAndServe(cfg serverConfig, srv *http.Server) error {
+	switch cfg.Mode {
+	case modeH1TLS, modeH2:
+		return srv.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile)
+	default:
+		return srv.ListenAndServe()
+	}
+}
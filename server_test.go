@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	"github.com/tmojzes/webservice/store"
+)
+
+// clientServerTest mirrors the helper of the same name in net/http's own
+// test suite: it spins up a real httptest.Server in either HTTP/1.1 or
+// HTTP/2 (over TLS) mode so protocol regressions in TimestampServer are
+// caught, not just its ServeHTTP logic in isolation.
+type clientServerTest struct {
+	t      *testing.T
+	h2Mode bool
+	ts     *httptest.Server
+	client *http.Client
+}
+
+func newClientServerTest(t *testing.T, h2Mode bool, handler http.Handler) *clientServerTest {
+	t.Helper()
+
+	cst := &clientServerTest{t: t, h2Mode: h2Mode}
+
+	if h2Mode {
+		ts := httptest.NewUnstartedServer(handler)
+		ts.EnableHTTP2 = true
+		ts.StartTLS()
+		cst.ts = ts
+	} else {
+		cst.ts = httptest.NewServer(handler)
+	}
+
+	cst.client = cst.ts.Client()
+
+	t.Cleanup(cst.ts.Close)
+
+	return cst
+}
+
+func (cst *clientServerTest) get(path string) (*http.Response, error) {
+	return cst.client.Get(cst.ts.URL + path)
+}
+
+func (cst *clientServerTest) post(path, body string) (*http.Response, error) {
+	return cst.client.Post(cst.ts.URL+path, contentTypeTextPlain, strings.NewReader(body))
+}
+
+func TestTimestampServerAcrossProtocolModes(t *testing.T) {
+	for _, h2Mode := range []bool{false, true} {
+		h2Mode := h2Mode
+
+		name := "h1"
+		if h2Mode {
+			name = "h2"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			timestamp := time.Now().Unix()
+			timestampStore := store.NewInMemory()
+			timestampStore.StoreTimestamp(timestamp)
+
+			cst := newClientServerTest(t, h2Mode, NewTimestampServer(Options{Store: timestampStore}))
+
+			t.Run("store and retrieve", func(t *testing.T) {
+				postTimestamp := time.Now().Add(time.Minute).Unix()
+
+				resp, err := cst.post("/timestamp", strconv.FormatInt(postTimestamp, 10))
+				assertNoError(t, err)
+				resp.Body.Close()
+				assertStatus(t, resp.StatusCode, http.StatusAccepted)
+
+				resp, err = cst.get("/timestamp")
+				assertNoError(t, err)
+				defer resp.Body.Close()
+				assertStatus(t, resp.StatusCode, http.StatusOK)
+
+				if h2Mode && resp.ProtoMajor != 2 {
+					t.Errorf("expected an HTTP/2 response, got ProtoMajor %d", resp.ProtoMajor)
+				}
+				if !h2Mode && resp.ProtoMajor != 1 {
+					t.Errorf("expected an HTTP/1.1 response, got ProtoMajor %d", resp.ProtoMajor)
+				}
+			})
+
+			t.Run("get timestamp", func(t *testing.T) {
+				resp, err := cst.get("/timestamp")
+				assertNoError(t, err)
+				defer resp.Body.Close()
+
+				assertStatus(t, resp.StatusCode, http.StatusOK)
+				assertContentType(t, resp.Header, contentTypeTextPlain)
+			})
+		})
+	}
+}
+
+// TestH2CServerWiring drives newHTTPServer and listenAndServe directly for
+// modeH2C, rather than httptest.Server's own TLS-backed HTTP/2 setup, so a
+// regression in the production wiring (h2c.NewHandler, the ListenAndServe
+// vs ListenAndServeTLS switch) would actually be caught.
+func TestH2CServerWiring(t *testing.T) {
+	timestamp := time.Now().Unix()
+	timestampStore := store.NewInMemory()
+	timestampStore.StoreTimestamp(timestamp)
+
+	cfg := serverConfig{Addr: freeAddr(t), Mode: modeH2C}
+
+	srv, err := newHTTPServer(cfg, NewTimestampServer(Options{Store: timestampStore}))
+	assertNoError(t, err)
+	t.Cleanup(func() { srv.Close() })
+
+	go listenAndServe(cfg, srv)
+	waitForServer(t, cfg.Addr)
+
+	// A client that speaks HTTP/2 over cleartext via prior knowledge
+	// proves modeH2C is actually engaged, not just that the port accepts
+	// HTTP/1.1 connections.
+	client := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+
+	resp, err := client.Get("http://" + cfg.Addr + "/timestamp")
+	assertNoError(t, err)
+	defer resp.Body.Close()
+
+	assertStatus(t, resp.StatusCode, http.StatusOK)
+
+	if resp.ProtoMajor != 2 {
+		t.Errorf("expected an HTTP/2 response over h2c, got ProtoMajor %d", resp.ProtoMajor)
+	}
+}
+
+// freeAddr returns a loopback address with a currently-free port, for
+// tests that need to pick cfg.Addr before starting a server.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assertNoError(t, err)
+
+	addr := l.Addr().String()
+	l.Close()
+
+	return addr
+}
+
+// waitForServer polls addr until something accepts TCP connections, since
+// listenAndServe runs in its own goroutine and isn't guaranteed to be
+// listening yet by the time this function returns.
+func waitForServer(t *testing.T, addr string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("server at %s never started listening", addr)
+}
@@ -0,0 +1,114 @@
+//go:build bolt
+
+package store
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var timestampsBucket = []byte("timestamps")
+
+func init() {
+	Register("bolt", NewBolt)
+}
+
+// Bolt stores every StoreTimestamp call as a key/value pair in a single
+// bbolt bucket, keyed by insertion order so History can range over it.
+type Bolt struct {
+	db *bolt.DB
+}
+
+func NewBolt(dataDir string) (Store, error) {
+	if dataDir == "" {
+		dataDir = "."
+	}
+
+	db, err := bolt.Open(filepath.Join(dataDir, "timestamps.bolt"), 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("store: open bolt db: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(timestampsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("store: create bucket: %w", err)
+	}
+
+	return &Bolt{db: db}, nil
+}
+
+func (s *Bolt) GetTimestamp() int64 {
+	history := s.History(1)
+	if len(history) == 0 {
+		return 0
+	}
+
+	return history[0]
+}
+
+func (s *Bolt) StoreTimestamp(timestamp int64) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(timestampsBucket)
+
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(seqKey(seq), timestampValue(timestamp))
+	})
+	if err != nil {
+		log.Printf("store: store timestamp: %v", err)
+	}
+}
+
+func (s *Bolt) History(limit int) []int64 {
+	var history []int64
+
+	s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(timestampsBucket).Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			history = append([]int64{int64(binary.BigEndian.Uint64(v))}, history...)
+			if limit > 0 && len(history) >= limit {
+				break
+			}
+		}
+		return nil
+	})
+
+	return history
+}
+
+func (s *Bolt) Delete() {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(timestampsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket(timestampsBucket)
+		return err
+	})
+	if err != nil {
+		log.Printf("store: delete: %v", err)
+	}
+}
+
+func seqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+func timestampValue(timestamp int64) []byte {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, uint64(timestamp))
+	return value
+}
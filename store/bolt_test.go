@@ -0,0 +1,30 @@
+//go:build bolt
+
+package store
+
+import "testing"
+
+func TestBoltStoreCrashRecovery(t *testing.T) {
+	dataDir := t.TempDir()
+
+	s, err := NewBolt(dataDir)
+	if err != nil {
+		t.Fatalf("NewBolt: %v", err)
+	}
+
+	s.StoreTimestamp(10)
+	s.StoreTimestamp(20)
+
+	bolt := s.(*Bolt)
+	if err := bolt.db.Close(); err != nil {
+		t.Fatalf("close bolt db: %v", err)
+	}
+
+	reopened, err := NewBolt(dataDir)
+	if err != nil {
+		t.Fatalf("reopen NewBolt: %v", err)
+	}
+
+	assertGetTimestamp(t, reopened, 20)
+	assertHistory(t, reopened.History(0), []int64{10, 20})
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const journalFileName = "timestamps.journal"
+
+func init() {
+	Register("file", NewFile)
+}
+
+// File is an append-only journal store: every StoreTimestamp call appends
+// a line and fsyncs before returning, so the last value survives a crash
+// and is replayed back into memory the next time the journal is opened.
+type File struct {
+	mu      sync.Mutex
+	file    *os.File
+	history []int64
+}
+
+func NewFile(dataDir string) (Store, error) {
+	if dataDir == "" {
+		dataDir = "."
+	}
+
+	path := filepath.Join(dataDir, journalFileName)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("store: open journal: %w", err)
+	}
+
+	history, err := replayJournal(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("store: replay journal: %w", err)
+	}
+
+	return &File{file: f, history: history}, nil
+}
+
+func replayJournal(f *os.File) ([]int64, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var history []int64
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		timestamp, err := strconv.ParseInt(line, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("corrupt journal entry %q: %w", line, err)
+		}
+
+		history = append(history, timestamp)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+func (s *File) GetTimestamp() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.history) == 0 {
+		return 0
+	}
+
+	return s.history[len(s.history)-1]
+}
+
+// LastModified mirrors InMemory's semantics: the stored timestamp is
+// itself interpreted as a time.Time.
+func (s *File) LastModified() time.Time {
+	timestamp := s.GetTimestamp()
+	if timestamp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(timestamp, 0)
+}
+
+// StoreTimestamp appends timestamp to the journal and fsyncs before
+// updating s.history, so the in-memory state never runs ahead of what's
+// durably on disk: a failed write or fsync (disk full, I/O error) is
+// logged and leaves history untouched rather than silently diverging
+// from the journal.
+func (s *File) StoreTimestamp(timestamp int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := fmt.Fprintln(s.file, timestamp); err != nil {
+		log.Printf("store: write journal entry: %v", err)
+		return
+	}
+
+	if err := s.file.Sync(); err != nil {
+		log.Printf("store: sync journal: %v", err)
+		return
+	}
+
+	s.history = append(s.history, timestamp)
+}
+
+func (s *File) History(limit int) []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return lastN(s.history, limit)
+}
+
+// Delete truncates the journal back to empty before clearing s.history,
+// logging and bailing out on a failed truncate/seek so history isn't
+// cleared unless the journal actually was.
+func (s *File) Delete() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.file.Truncate(0); err != nil {
+		log.Printf("store: truncate journal: %v", err)
+		return
+	}
+
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		log.Printf("store: seek journal: %v", err)
+		return
+	}
+
+	s.history = nil
+}
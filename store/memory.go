@@ -0,0 +1,66 @@
+package store
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+func init() {
+	Register("memory", func(string) (Store, error) { return NewInMemory(), nil })
+}
+
+// InMemory is the default Store backend: state lives only in the process
+// and is lost on restart.
+type InMemory struct {
+	timestamp atomic.Value
+
+	mu      sync.Mutex
+	history []int64
+}
+
+func NewInMemory() *InMemory {
+	s := &InMemory{}
+	s.timestamp.Store(time.Time{})
+
+	return s
+}
+
+func (s *InMemory) GetTimestamp() int64 {
+	t := s.timestamp.Load().(time.Time)
+
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
+
+// LastModified returns the time.Time backing the currently stored
+// timestamp, satisfying the server's optional cache-validator interface.
+func (s *InMemory) LastModified() time.Time {
+	return s.timestamp.Load().(time.Time)
+}
+
+func (s *InMemory) StoreTimestamp(timestamp int64) {
+	s.timestamp.Store(time.Unix(timestamp, 0))
+
+	s.mu.Lock()
+	s.history = append(s.history, timestamp)
+	s.mu.Unlock()
+}
+
+func (s *InMemory) History(limit int) []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return lastN(s.history, limit)
+}
+
+func (s *InMemory) Delete() {
+	s.timestamp.Store(time.Time{})
+
+	s.mu.Lock()
+	s.history = nil
+	s.mu.Unlock()
+}
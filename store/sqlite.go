@@ -0,0 +1,95 @@
+//go:build sqlite
+
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	Register("sqlite", NewSQLite)
+}
+
+// SQLite stores every StoreTimestamp call as a row in a single table,
+// using modernc.org/sqlite so the binary stays cgo-free.
+type SQLite struct {
+	db *sql.DB
+}
+
+func NewSQLite(dataDir string) (Store, error) {
+	if dataDir == "" {
+		dataDir = "."
+	}
+
+	db, err := sql.Open("sqlite", filepath.Join(dataDir, "timestamps.sqlite"))
+	if err != nil {
+		return nil, fmt.Errorf("store: open sqlite db: %w", err)
+	}
+
+	const schema = `CREATE TABLE IF NOT EXISTS timestamps (
+		id        INTEGER PRIMARY KEY AUTOINCREMENT,
+		timestamp INTEGER NOT NULL
+	)`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("store: create schema: %w", err)
+	}
+
+	return &SQLite{db: db}, nil
+}
+
+func (s *SQLite) GetTimestamp() int64 {
+	var timestamp int64
+
+	err := s.db.QueryRow(`SELECT timestamp FROM timestamps ORDER BY id DESC LIMIT 1`).Scan(&timestamp)
+	if err != nil {
+		return 0
+	}
+
+	return timestamp
+}
+
+func (s *SQLite) StoreTimestamp(timestamp int64) {
+	if _, err := s.db.Exec(`INSERT INTO timestamps (timestamp) VALUES (?)`, timestamp); err != nil {
+		log.Printf("store: store timestamp: %v", err)
+	}
+}
+
+func (s *SQLite) History(limit int) []int64 {
+	query := `SELECT timestamp FROM timestamps ORDER BY id DESC`
+	if limit > 0 {
+		query += fmt.Sprintf(` LIMIT %d`, limit)
+	}
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var reversed []int64
+	for rows.Next() {
+		var timestamp int64
+		if rows.Scan(&timestamp) == nil {
+			reversed = append(reversed, timestamp)
+		}
+	}
+
+	history := make([]int64, len(reversed))
+	for i, timestamp := range reversed {
+		history[len(reversed)-1-i] = timestamp
+	}
+
+	return history
+}
+
+func (s *SQLite) Delete() {
+	if _, err := s.db.Exec(`DELETE FROM timestamps`); err != nil {
+		log.Printf("store: delete: %v", err)
+	}
+}
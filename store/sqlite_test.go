@@ -0,0 +1,30 @@
+//go:build sqlite
+
+package store
+
+import "testing"
+
+func TestSQLiteStoreCrashRecovery(t *testing.T) {
+	dataDir := t.TempDir()
+
+	s, err := NewSQLite(dataDir)
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+
+	s.StoreTimestamp(10)
+	s.StoreTimestamp(20)
+
+	sqlite := s.(*SQLite)
+	if err := sqlite.db.Close(); err != nil {
+		t.Fatalf("close sqlite db: %v", err)
+	}
+
+	reopened, err := NewSQLite(dataDir)
+	if err != nil {
+		t.Fatalf("reopen NewSQLite: %v", err)
+	}
+
+	assertGetTimestamp(t, reopened, 20)
+	assertHistory(t, reopened.History(0), []int64{10, 20})
+}
@@ -0,0 +1,80 @@
+// Package store provides pluggable, named backends for persisting the
+// timestamp server's state. Backends register themselves via Register
+// and are looked up by name with Open, so the CLI can select one with a
+// flag without the rest of the program depending on a concrete type.
+package store
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Store is the persistence contract every backend must satisfy.
+type Store interface {
+	GetTimestamp() int64
+	StoreTimestamp(timestamp int64)
+	History(limit int) []int64
+	Delete()
+}
+
+// Factory builds a Store rooted at dataDir, which backends that persist
+// to disk use to locate their files; in-memory backends ignore it.
+type Factory func(dataDir string) (Store, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Register adds a named backend factory. It is meant to be called from
+// an init() function and panics on a duplicate name, mirroring how the
+// standard library's database/sql driver registry behaves.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, exists := factories[name]; exists {
+		panic("store: Register called twice for backend " + name)
+	}
+
+	factories[name] = factory
+}
+
+// Open builds a Store using the named backend's factory.
+func Open(name, dataDir string) (Store, error) {
+	mu.RLock()
+	factory, ok := factories[name]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("store: unknown backend %q (known: %v)", name, Names())
+	}
+
+	return factory(dataDir)
+}
+
+// Names returns the registered backend names.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// lastN returns the last limit elements of items, or all of them when
+// limit is <= 0 or larger than len(items).
+func lastN(items []int64, limit int) []int64 {
+	if limit <= 0 || limit > len(items) {
+		limit = len(items)
+	}
+
+	out := make([]int64, limit)
+	copy(out, items[len(items)-limit:])
+
+	return out
+}
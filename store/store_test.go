@@ -0,0 +1,98 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInMemoryStore(t *testing.T) {
+	s := NewInMemory()
+
+	assertNoTimestamp(t, s)
+
+	s.StoreTimestamp(1)
+	s.StoreTimestamp(2)
+	s.StoreTimestamp(3)
+
+	assertGetTimestamp(t, s, 3)
+	assertHistory(t, s.History(2), []int64{2, 3})
+	assertHistory(t, s.History(0), []int64{1, 2, 3})
+
+	s.Delete()
+	assertNoTimestamp(t, s)
+}
+
+func TestFileStoreCrashRecovery(t *testing.T) {
+	dataDir := t.TempDir()
+
+	s, err := NewFile(dataDir)
+	if err != nil {
+		t.Fatalf("NewFile: %v", err)
+	}
+
+	s.StoreTimestamp(10)
+	s.StoreTimestamp(20)
+
+	reopened, err := NewFile(dataDir)
+	if err != nil {
+		t.Fatalf("reopen NewFile: %v", err)
+	}
+
+	assertGetTimestamp(t, reopened, 20)
+	assertHistory(t, reopened.History(0), []int64{10, 20})
+}
+
+func TestFileStoreJournalPath(t *testing.T) {
+	dataDir := t.TempDir()
+
+	if _, err := NewFile(dataDir); err != nil {
+		t.Fatalf("NewFile: %v", err)
+	}
+
+	if _, err := NewFile(dataDir); err != nil {
+		t.Fatalf("NewFile: %v", err)
+	}
+
+	want := filepath.Join(dataDir, journalFileName)
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("expected journal file at %s, %v", want, err)
+	}
+}
+
+func TestOpenUnknownBackend(t *testing.T) {
+	if _, err := Open("nonexistent", t.TempDir()); err == nil {
+		t.Error("expected an error for an unknown backend, got none")
+	}
+}
+
+func assertNoTimestamp(t testing.TB, s Store) {
+	t.Helper()
+
+	if got := s.GetTimestamp(); got != 0 {
+		t.Errorf("expected no timestamp, got %d", got)
+	}
+}
+
+func assertGetTimestamp(t testing.TB, s Store, want int64) {
+	t.Helper()
+
+	if got := s.GetTimestamp(); got != want {
+		t.Errorf("got %d want %d", got, want)
+	}
+}
+
+func assertHistory(t testing.TB, got, want []int64) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got history %v want %v", got, want)
+	}
+
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("got history %v want %v", got, want)
+			return
+		}
+	}
+}